@@ -0,0 +1,103 @@
+// Package frontmatter renders the YAML/TOML metadata block written
+// above a converted page, from either a named built-in template or a
+// user-supplied text/template file.
+package frontmatter
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// PageMeta is the data context exposed to frontmatter templates.
+type PageMeta struct {
+	Title         string
+	SourcePath    string
+	Date          string
+	ExtractedText string
+	FirstImage    string
+	Vars          map[string]string
+}
+
+// Builtin names a template.
+const (
+	Hugo   = "hugo"
+	Jekyll = "jekyll"
+	Zola   = "zola"
+	Astro  = "astro"
+)
+
+var builtin = map[string]string{
+	Hugo:   hugoTemplate,
+	Jekyll: jekyllTemplate,
+	Zola:   zolaTemplate,
+	Astro:  astroTemplate,
+}
+
+const hugoTemplate = `---
+title: "{{.Title}}"
+description: "{{.Title}}"
+meta_title: "{{.Title}}"
+author: ""
+date: {{.Date}}
+categories: ["{{.Vars.category}}"]
+image: ""
+tags: ["{{.Vars.tag}}"]
+draft: false
+---
+
+`
+
+const jekyllTemplate = `---
+layout: post
+title: "{{.Title}}"
+date: {{.Date}}
+categories: [{{.Vars.category}}]
+tags: [{{.Vars.tag}}]
+---
+
+`
+
+const zolaTemplate = `+++
+title = "{{.Title}}"
+date = {{.Date}}
+draft = false
+
+[taxonomies]
+categories = ["{{.Vars.category}}"]
+tags = ["{{.Vars.tag}}"]
++++
+
+`
+
+const astroTemplate = `---
+title: "{{.Title}}"
+pubDate: "{{.Date}}"
+heroImage: "{{.FirstImage}}"
+tags: ["{{.Vars.tag}}"]
+---
+
+`
+
+// Load returns the template to render frontmatter with: the file at
+// customPath if one is given, otherwise the named built-in template.
+func Load(name, customPath string) (*template.Template, error) {
+	if customPath != "" {
+		return template.ParseFiles(customPath)
+	}
+	text, ok := builtin[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown frontmatter template %q (known: hugo, jekyll, zola, astro)", name)
+	}
+	return template.New(name).Parse(text)
+}
+
+// Render executes tmpl against meta, returning the frontmatter block
+// including its own leading/trailing document markers.
+func Render(tmpl *template.Template, meta PageMeta) (string, error) {
+	var b strings.Builder
+	if err := tmpl.Execute(&b, meta); err != nil {
+		return "", fmt.Errorf("rendering frontmatter template: %w", err)
+	}
+	return b.String(), nil
+}
@@ -0,0 +1,57 @@
+package frontmatter
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadBuiltinTemplates(t *testing.T) {
+	for _, name := range []string{Hugo, Jekyll, Zola, Astro} {
+		t.Run(name, func(t *testing.T) {
+			tmpl, err := Load(name, "")
+			if err != nil {
+				t.Fatalf("Load(%q): %v", name, err)
+			}
+			out, err := Render(tmpl, PageMeta{
+				Title: "My Page",
+				Date:  "2026-07-26",
+				Vars:  map[string]string{"category": "travel", "tag": "japan"},
+			})
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if !strings.Contains(out, "My Page") {
+				t.Errorf("expected title in rendered frontmatter, got %q", out)
+			}
+		})
+	}
+}
+
+func TestLoadUnknownBuiltinFails(t *testing.T) {
+	if _, err := Load("nonexistent", ""); err == nil {
+		t.Fatal("expected an error for an unknown built-in template name")
+	}
+}
+
+func TestLoadCustomTemplateOverridesBuiltin(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "custom.tmpl")
+	content := "---\ncustom_title: {{.Title}}\nimage: {{.FirstImage}}\n---\n\n"
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("writing custom template: %v", err)
+	}
+
+	tmpl, err := Load(Hugo, path)
+	if err != nil {
+		t.Fatalf("Load with custom path: %v", err)
+	}
+	out, err := Render(tmpl, PageMeta{Title: "Custom", FirstImage: "hero.jpg"})
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(out, "custom_title: Custom") || !strings.Contains(out, "image: hero.jpg") {
+		t.Errorf("expected custom template fields, got %q", out)
+	}
+}
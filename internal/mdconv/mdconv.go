@@ -0,0 +1,245 @@
+// Package mdconv converts parsed HTML documents into CommonMark/GFM
+// markdown without shelling out to an external tool such as pandoc.
+package mdconv
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// whitespaceRun matches a run of one or more whitespace characters,
+// including newlines, so multi-line source text collapses onto one
+// markdown line.
+var whitespaceRun = regexp.MustCompile(`\s+`)
+
+// LinkRewriter rewrites a link or image href before it is emitted. It is
+// the hook point for behaviour that used to be done with regexes on the
+// final pandoc output (e.g. turning "../about/index.html" into "about").
+type LinkRewriter func(href string) string
+
+// Options controls how a document is converted to markdown.
+type Options struct {
+	// RewriteLink, when set, is applied to every <a href> and <img src>
+	// before it is written out. A nil RewriteLink leaves hrefs untouched.
+	RewriteLink LinkRewriter
+}
+
+// StripIndexHTML is a ready-made LinkRewriter that removes a leading
+// "../" and trailing "/index.html" from a link target, matching the
+// tidy-up the old pandoc post-processing step used to do by hand.
+func StripIndexHTML(href string) string {
+	href = strings.TrimPrefix(href, "../")
+	href = strings.TrimSuffix(href, "/index.html")
+	if href == "" {
+		href = "."
+	}
+	return href
+}
+
+// Converter walks an *html.Node tree and renders it as markdown.
+type Converter struct {
+	opts Options
+	buf  strings.Builder
+}
+
+// New returns a Converter configured with opts.
+func New(opts Options) *Converter {
+	return &Converter{opts: opts}
+}
+
+// Convert renders n and its descendants as markdown using the given
+// options. It is the direct replacement for shelling out to
+// `pandoc -f html -t markdown`.
+func Convert(n *html.Node, opts Options) string {
+	c := New(opts)
+	c.block(n)
+	return strings.Trim(c.buf.String(), "\n") + "\n"
+}
+
+// Convert renders n and its descendants using the converter's options.
+func (c *Converter) Convert(n *html.Node) string {
+	c.block(n)
+	return strings.Trim(c.buf.String(), "\n") + "\n"
+}
+
+func (c *Converter) rewrite(href string) string {
+	if c.opts.RewriteLink == nil {
+		return href
+	}
+	return c.opts.RewriteLink(href)
+}
+
+// block renders n's children as block-level markdown, recursing into
+// elements that themselves contain block content.
+func (c *Converter) block(n *html.Node) {
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		c.blockNode(ch)
+	}
+}
+
+func (c *Converter) blockNode(n *html.Node) {
+	if n.Type == html.TextNode {
+		if strings.TrimSpace(n.Data) != "" {
+			c.buf.WriteString(strings.TrimSpace(n.Data))
+			c.buf.WriteString("\n\n")
+		}
+		return
+	}
+
+	if n.Type != html.ElementNode {
+		c.block(n)
+		return
+	}
+
+	switch n.Data {
+	case "h1", "h2", "h3", "h4", "h5", "h6":
+		level, _ := strconv.Atoi(strings.TrimPrefix(n.Data, "h"))
+		c.buf.WriteString(strings.Repeat("#", level))
+		c.buf.WriteString(" ")
+		c.buf.WriteString(c.inline(n))
+		c.buf.WriteString("\n\n")
+	case "p":
+		c.buf.WriteString(c.inline(n))
+		c.buf.WriteString("\n\n")
+	case "blockquote":
+		text := strings.TrimSpace(c.renderChildBlocks(n))
+		for _, line := range strings.Split(text, "\n") {
+			c.buf.WriteString("> ")
+			c.buf.WriteString(line)
+			c.buf.WriteString("\n")
+		}
+		c.buf.WriteString("\n")
+	case "ul":
+		c.list(n, false)
+		c.buf.WriteString("\n")
+	case "ol":
+		c.list(n, true)
+		c.buf.WriteString("\n")
+	case "pre":
+		lang := ""
+		code := n
+		if n.FirstChild != nil && n.FirstChild.Type == html.ElementNode && n.FirstChild.Data == "code" {
+			code = n.FirstChild
+			lang = codeLang(code)
+		}
+		c.buf.WriteString("```")
+		c.buf.WriteString(lang)
+		c.buf.WriteString("\n")
+		c.buf.WriteString(strings.Trim(getText(code), "\n"))
+		c.buf.WriteString("\n```\n\n")
+	case "br":
+		c.buf.WriteString("\n")
+	case "hr":
+		c.buf.WriteString("---\n\n")
+	case "a", "img":
+		c.buf.WriteString(c.inlineNode(n))
+		c.buf.WriteString("\n\n")
+	case "html", "body", "div", "section", "article", "main", "header", "footer", "nav":
+		c.block(n)
+	default:
+		c.block(n)
+	}
+}
+
+func (c *Converter) renderChildBlocks(n *html.Node) string {
+	inner := New(c.opts)
+	inner.block(n)
+	return inner.buf.String()
+}
+
+func (c *Converter) list(n *html.Node, ordered bool) {
+	i := 1
+	for li := n.FirstChild; li != nil; li = li.NextSibling {
+		if li.Type != html.ElementNode || li.Data != "li" {
+			continue
+		}
+		marker := "-"
+		if ordered {
+			marker = strconv.Itoa(i) + "."
+			i++
+		}
+		c.buf.WriteString(marker)
+		c.buf.WriteString(" ")
+		c.buf.WriteString(c.inline(li))
+		c.buf.WriteString("\n")
+	}
+}
+
+func codeLang(code *html.Node) string {
+	for _, attr := range code.Attr {
+		if attr.Key == "class" && strings.HasPrefix(attr.Val, "language-") {
+			return strings.TrimPrefix(attr.Val, "language-")
+		}
+	}
+	return ""
+}
+
+// inline renders n's children as inline markdown (emphasis, links,
+// images, code spans), flattening any nested block elements into text.
+// Whitespace is normalized per text node as it's rendered, so code
+// spans and hard breaks pass through untouched instead of being
+// squashed by a collapse over the fully assembled string.
+func (c *Converter) inline(n *html.Node) string {
+	var b strings.Builder
+	for ch := n.FirstChild; ch != nil; ch = ch.NextSibling {
+		b.WriteString(c.inlineNode(ch))
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (c *Converter) inlineNode(n *html.Node) string {
+	if n.Type == html.TextNode {
+		return whitespaceRun.ReplaceAllString(n.Data, " ")
+	}
+	if n.Type != html.ElementNode {
+		return c.inline(n)
+	}
+
+	switch n.Data {
+	case "strong", "b":
+		return "**" + c.inline(n) + "**"
+	case "em", "i":
+		return "*" + c.inline(n) + "*"
+	case "code":
+		return "`" + getText(n) + "`"
+	case "a":
+		href := c.rewrite(attr(n, "href"))
+		return "[" + c.inline(n) + "](" + href + ")"
+	case "img":
+		return "![" + attr(n, "alt") + "](" + c.rewrite(attr(n, "src")) + ")"
+	case "br":
+		// Two trailing spaces before the newline is CommonMark's hard
+		// line break, so the break survives markdown rendering instead
+		// of being collapsed back into a single space.
+		return "  \n"
+	default:
+		return c.inline(n)
+	}
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+func getText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
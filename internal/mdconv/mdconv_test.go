@@ -0,0 +1,62 @@
+package mdconv
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func parseFragment(t *testing.T, body string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader("<html><body>" + body + "</body></html>"))
+	if err != nil {
+		t.Fatalf("parsing fragment: %v", err)
+	}
+	return doc
+}
+
+func TestConvertPreservesHardBreak(t *testing.T) {
+	doc := parseFragment(t, "<p>Line one<br>Line two</p>")
+	got := Convert(doc, Options{})
+	if !strings.Contains(got, "Line one  \nLine two") {
+		t.Fatalf("expected a hard line break between the lines, got %q", got)
+	}
+}
+
+func TestConvertPreservesCodeWhitespace(t *testing.T) {
+	doc := parseFragment(t, "<p><code>if (x)  { y }</code></p>")
+	got := Convert(doc, Options{})
+	want := "`if (x)  { y }`"
+	if !strings.Contains(got, want) {
+		t.Fatalf("expected code span whitespace untouched (%q), got %q", want, got)
+	}
+}
+
+func TestConvertCollapsesRunsOfPlainWhitespace(t *testing.T) {
+	doc := parseFragment(t, "<p>Hello   \n  world</p>")
+	got := strings.TrimSpace(Convert(doc, Options{}))
+	if got != "Hello world" {
+		t.Fatalf("expected collapsed whitespace in plain text, got %q", got)
+	}
+}
+
+func TestConvertHeadingsAndLists(t *testing.T) {
+	doc := parseFragment(t, "<h1>Title</h1><ul><li>one</li><li>two</li></ul>")
+	got := Convert(doc, Options{})
+	if !strings.Contains(got, "# Title") {
+		t.Fatalf("expected a level-1 heading, got %q", got)
+	}
+	if !strings.Contains(got, "- one") || !strings.Contains(got, "- two") {
+		t.Fatalf("expected both list items, got %q", got)
+	}
+}
+
+func TestConvertRewritesLinks(t *testing.T) {
+	doc := parseFragment(t, `<p><a href="../about/index.html">About</a></p>`)
+	got := Convert(doc, Options{RewriteLink: StripIndexHTML})
+	want := "[About](about)"
+	if !strings.Contains(got, want) {
+		t.Fatalf("expected rewritten link %q, got %q", want, got)
+	}
+}
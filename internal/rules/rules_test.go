@@ -0,0 +1,97 @@
+package rules
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/html"
+)
+
+func firstElement(t *testing.T, fragment string, tag string) *html.Node {
+	t.Helper()
+	doc, err := html.Parse(strings.NewReader("<html><body>" + fragment + "</body></html>"))
+	if err != nil {
+		t.Fatalf("parsing fragment: %v", err)
+	}
+	var found *html.Node
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if found != nil {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == tag {
+			found = n
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	if found == nil {
+		t.Fatalf("no <%s> found in fragment %q", tag, fragment)
+	}
+	return found
+}
+
+func TestDefaultConfigMatchesKnownCases(t *testing.T) {
+	matcher := DefaultConfig().Matcher()
+
+	cases := []struct {
+		name     string
+		fragment string
+		tag      string
+		want     bool
+	}{
+		{"plain ul kept", `<ul><li>a</li></ul>`, "ul", true},
+		{"ul with id dropped", `<ul id="x"><li>a</li></ul>`, "ul", false},
+		{"plain p kept", `<p>hello</p>`, "p", true},
+		{"copyright p dropped", `<p>Copyright 2024</p>`, "p", false},
+		{"classed p dropped", `<p class="x">hello</p>`, "p", false},
+		{"district map h3 dropped", `<h3>Tokyo District Map</h3>`, "h3", false},
+		{"plain h3 kept", `<h3>Other heading</h3>`, "h3", true},
+		{"photogimg div kept", `<div class="photogimg"></div>`, "div", true},
+		{"other div dropped", `<div class="other"></div>`, "div", false},
+		{"span always dropped", `<span>x</span>`, "span", false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			n := firstElement(t, tc.fragment, tc.tag)
+			if got := matcher.Match(n); got != tc.want {
+				t.Errorf("Match(%q) = %v, want %v", tc.fragment, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestLoadParsesCustomConfig(t *testing.T) {
+	dir := t.TempDir()
+	path := dir + "/rules.yaml"
+	yamlContent := []byte(`
+rules:
+  - tag: span
+    keep: true
+    attr_equals:
+      class: keep-me
+`)
+	if err := os.WriteFile(path, yamlContent, 0644); err != nil {
+		t.Fatalf("writing temp config: %v", err)
+	}
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	matcher := cfg.Matcher()
+
+	kept := firstElement(t, `<span class="keep-me">a</span>`, "span")
+	if !matcher.Match(kept) {
+		t.Errorf("expected span.keep-me to be kept")
+	}
+	dropped := firstElement(t, `<span class="other">a</span>`, "span")
+	if matcher.Match(dropped) {
+		t.Errorf("expected span.other to be dropped")
+	}
+}
@@ -2,79 +2,24 @@ package main
 
 import (
 	"bytes"
+	"flag"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
-	"os/exec"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 
+	"github.com/truong11t2/clean-html/internal/frontmatter"
+	"github.com/truong11t2/clean-html/internal/mdconv"
+	"github.com/truong11t2/clean-html/internal/rules"
 	"golang.org/x/net/html"
 )
 
-// hasNoAttributes checks if the node has no specific attributes
-func hasNoAttributes(n *html.Node, excludeAttrs []string) bool {
-	for _, attr := range n.Attr {
-		for _, excludeAttr := range excludeAttrs {
-			if attr.Key == excludeAttr {
-				return false
-			}
-		}
-	}
-	return true
-}
-
-// getTextContent gets all text content from a node and its children
-func getTextContent(n *html.Node) string {
-	var text string
-	if n.Type == html.TextNode {
-		text = n.Data
-	}
-	for c := n.FirstChild; c != nil; c = c.NextSibling {
-		text += getTextContent(c)
-	}
-	return strings.TrimSpace(text)
-}
-
-// isTargetElement checks if the node is one we want to keep
-func isTargetElement(n *html.Node) bool {
-	if n.Type != html.ElementNode {
-		return false
-	}
-
-	switch n.Data {
-	case "ul":
-		// Keep <ul> tags with no id or style
-		return hasNoAttributes(n, []string{"id", "style"})
-	case "p":
-		// Keep <p> tags with no class and no "Copyright" in content
-		if !hasNoAttributes(n, []string{"class", "style"}) {
-			return false
-		}
-		content := getTextContent(n)
-		return !strings.Contains(content, "Copyright")
-	case "h3":
-		// Keep <h3> tags with no class or id and no "Tokyo District Map" in content
-		if !hasNoAttributes(n, []string{"class", "id"}) {
-			return false
-		}
-		content := getTextContent(n)
-		return !strings.Contains(content, "District Map")
-	case "h1", "h2":
-		// Keep heading tags with no class or id
-		return hasNoAttributes(n, []string{"class", "id"})
-	case "div":
-		// Check for div with class="photogimg"
-		for _, attr := range n.Attr {
-			if attr.Key == "class" && attr.Val == "photogimg" {
-				return true
-			}
-		}
-	}
-	return false
-}
-
 // renderNode converts a node back to HTML string
 func renderNode(n *html.Node) string {
 	var buf bytes.Buffer
@@ -83,13 +28,34 @@ func renderNode(n *html.Node) string {
 	return buf.String()
 }
 
-// extractContent processes the HTML and returns extracted content
-func extractContent(doc *html.Node) []string {
+// extractedHTMLTemplate wraps the extracted nodes back into a minimal
+// HTML document, used both for the batch _processed.html output and
+// for the serve command's /extracted/ and /markdown/ views.
+const extractedHTMLTemplate = `<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="utf-8">
+</head>
+<body>
+%s
+</body>
+</html>`
+
+// renderExtractedHTML joins the extracted node renderings into one
+// HTML document string.
+func renderExtractedHTML(extracted []string) string {
+	return fmt.Sprintf(extractedHTMLTemplate, strings.Join(extracted, "\n"))
+}
+
+// extractContent walks doc and returns the rendered HTML of every node
+// that matcher says to keep, descending into a node's children only
+// when the node itself is dropped.
+func extractContent(doc *html.Node, matcher rules.Matcher) []string {
 	var extracted []string
 	var f func(*html.Node)
 
 	f = func(n *html.Node) {
-		if isTargetElement(n) {
+		if matcher.Match(n) {
 			extracted = append(extracted, renderNode(n))
 		} else {
 			for c := n.FirstChild; c != nil; c = c.NextSibling {
@@ -101,6 +67,67 @@ func extractContent(doc *html.Node) []string {
 	return extracted
 }
 
+// firstHeadingText returns the text of the first <h1> under doc, or ""
+// if there is none.
+func firstHeadingText(doc *html.Node) string {
+	var found string
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if found != "" {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "h1" {
+			found = strings.TrimSpace(getText(n))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return found
+}
+
+// firstImageSrc returns the src of the first <img> under doc, or "" if
+// there is none.
+func firstImageSrc(doc *html.Node) string {
+	var found string
+	var f func(*html.Node)
+	f = func(n *html.Node) {
+		if found != "" {
+			return
+		}
+		if n.Type == html.ElementNode && n.Data == "img" {
+			for _, a := range n.Attr {
+				if a.Key == "src" {
+					found = a.Val
+					return
+				}
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			f(c)
+		}
+	}
+	f(doc)
+	return found
+}
+
+func getText(n *html.Node) string {
+	var b strings.Builder
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			b.WriteString(n.Data)
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return b.String()
+}
+
 func formatDirName(name string) string {
 	// Replace hyphens with spaces
 	words := strings.Split(name, "-")
@@ -136,140 +163,206 @@ func checkAndCreateOutputDir(outputDir string) error {
 	return nil
 }
 
-func processHTMLFile(inputFile string, outputDir string, category string, tag string) error {
+// ProcessStats summarizes what processHTMLFile did to one file, for the
+// structured per-file log record.
+type ProcessStats struct {
+	InputBytes  int
+	OutputBytes int
+	Matches     int
+}
+
+func processHTMLFile(inputFile string, inputDir string, outputDir string, matcher rules.Matcher, tmpl *template.Template, vars map[string]string) (ProcessStats, error) {
+	var stats ProcessStats
+
 	// Get the HTML file name without extension for the markdown file
 	baseFileName := filepath.Base(inputFile)
 	fileNameWithoutExt := strings.TrimSuffix(baseFileName, filepath.Ext(baseFileName))
 
 	outputFile := strings.TrimSuffix(inputFile, ".html") + "_processed.html"
-	mdOutputFile := filepath.Join(outputDir, fileNameWithoutExt+".md")
+
+	// Mirror the file's subdirectory under outputDir so two files with
+	// the same basename in different subdirectories (e.g. two
+	// index.html) don't race to clobber the same output path.
+	relDir, err := filepath.Rel(inputDir, filepath.Dir(inputFile))
+	if err != nil {
+		return stats, fmt.Errorf("error resolving relative path: %v", err)
+	}
+	mdOutputDir := filepath.Join(outputDir, relDir)
+	if err := os.MkdirAll(mdOutputDir, 0755); err != nil {
+		return stats, fmt.Errorf("error creating output subdirectory: %v", err)
+	}
+	mdOutputFile := filepath.Join(mdOutputDir, fileNameWithoutExt+".md")
 
 	// Read input file
 	content, err := os.ReadFile(inputFile)
 	if err != nil {
-		return fmt.Errorf("error reading file: %v", err)
+		return stats, fmt.Errorf("error reading file: %v", err)
 	}
+	stats.InputBytes = len(content)
 
 	// Parse HTML
 	doc, err := html.Parse(strings.NewReader(string(content)))
 	if err != nil {
-		return fmt.Errorf("error parsing HTML: %v", err)
+		return stats, fmt.Errorf("error parsing HTML: %v", err)
 	}
 
 	// Extract content
-	extractedContent := extractContent(doc)
-
-	// Create new HTML document
-	newHTML := `<!DOCTYPE html>
-<html>
-<head>
-    <meta charset="utf-8">
-</head>
-<body>
-%s
-</body>
-</html>`
-
-	// Join extracted content
-	contentStr := strings.Join(extractedContent, "\n")
+	extractedContent := extractContent(doc, matcher)
+	stats.Matches = len(extractedContent)
 
 	// Create final HTML
-	outputHTML := fmt.Sprintf(newHTML, contentStr)
+	outputHTML := renderExtractedHTML(extractedContent)
 
 	// Write to output file
 	err = os.WriteFile(outputFile, []byte(outputHTML), 0644)
 	if err != nil {
-		return fmt.Errorf("error writing output file: %v", err)
+		return stats, fmt.Errorf("error writing output file: %v", err)
 	}
 
-	fmt.Printf("Successfully extracted content to %s\n", outputFile)
-
-	// Generate markdown using pandoc
-	cmd := exec.Command("pandoc", "-f", "html", "-t", "markdown", outputFile, "-o", mdOutputFile)
-	if err := cmd.Run(); err != nil {
-		return fmt.Errorf("error executing pandoc: %v", err)
+	// Convert the extracted HTML straight to markdown; no external
+	// tool and no output file round-trip needed.
+	extractedDoc, err := html.Parse(strings.NewReader(outputHTML))
+	if err != nil {
+		return stats, fmt.Errorf("error parsing extracted HTML: %v", err)
 	}
+	mdText := mdconv.Convert(extractedDoc, mdconv.Options{RewriteLink: mdconv.StripIndexHTML})
 
-	fmt.Printf("Successfully converted to markdown: %s\n", mdOutputFile)
+	// Prefer the page's own first <h1> as the title; fall back to the
+	// filename only when the page has none.
+	title := firstHeadingText(extractedDoc)
+	if title == "" {
+		title = formatDirName(fileNameWithoutExt)
+	}
 
-	// Read the markdown file
-	mdContent, err := os.ReadFile(mdOutputFile)
+	fm, err := frontmatter.Render(tmpl, frontmatter.PageMeta{
+		Title:         title,
+		SourcePath:    inputFile,
+		Date:          time.Now().Format("2006-01-02"),
+		ExtractedText: mdText,
+		FirstImage:    firstImageSrc(extractedDoc),
+		Vars:          vars,
+	})
 	if err != nil {
-		return fmt.Errorf("error reading markdown file: %v", err)
+		return stats, err
 	}
 
-	var result strings.Builder
-	// Use the file name instead of parent directory name
-	title := formatDirName(fileNameWithoutExt)
-
-	// Build metadata
-	result.WriteString("---\n")
-	result.WriteString("title: \"" + title + "\"\n")
-	result.WriteString("description: \"" + title + "\"\n")
-	result.WriteString("meta_title: \"" + title + "\"\n")
-	result.WriteString("author: " + "\"\"" + "\n")
-	result.WriteString("date: " + time.Now().Format("2006-01-02") + "\n")
-	result.WriteString("categories: [\"" + category + "\"]\n")
-	result.WriteString("image: " + "\"\"" + "\n")
-	result.WriteString("tags: [\"" + tag + "\"]\n")
-	result.WriteString("draft: " + "false" + "\n")
-	result.WriteString("---\n\n")
-
-	mdText := string(mdContent)
-	// First remove ::
-	mdText = strings.ReplaceAll(mdText, "**::**", "")
-	// Remove lines starting with :::
-	lines := strings.Split(mdText, "\n")
-	var filteredLines []string
-	for _, line := range lines {
-		if strings.HasPrefix(strings.TrimSpace(line), ":::") {
-			continue
-		}
+	// Write the markdown file
+	mdBytes := []byte(fm + mdText)
+	if err := os.WriteFile(mdOutputFile, mdBytes, 0644); err != nil {
+		return stats, fmt.Errorf("error writing markdown file: %v", err)
+	}
+	stats.OutputBytes = len(mdBytes)
 
-		// Replace link patterns
-		line = strings.ReplaceAll(line, "(../", "(")
-		line = strings.ReplaceAll(line, "/index.html)", ")")
-		filteredLines = append(filteredLines, line)
+	return stats, nil
+}
 
-	}
-	mdText = strings.Join(filteredLines, "\n")
-	// Process the entire text as one string
-	for i := 0; i < len(string(mdText)); i++ {
-		if string(mdText)[i] == '{' {
-			// Find the closing brace
-			j := i
-			for j < len(string(mdText)) && string(mdText)[j] != '}' {
-				j++
-			}
-			if j < len(string(mdText)) {
-				// Skip past the closing brace
-				i = j
-				continue
-			}
+// fileOutcome is what a worker reports back to the collector for a
+// single path pulled off the walk channel.
+type fileOutcome struct {
+	Path     string
+	Status   string // "success", "skipped", or "error"
+	Err      error
+	Duration time.Duration
+	Stats    ProcessStats
+}
+
+// walkFiles feeds every regular file under root into paths, closing it
+// when the walk finishes, and reports the walk error (if any) on errc.
+func walkFiles(root string, paths chan<- string, errc chan<- error) {
+	defer close(paths)
+	errc <- filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
 		}
-		result.WriteByte(string(mdText)[i])
-	}
+		if !info.IsDir() {
+			paths <- path
+		}
+		return nil
+	})
+}
 
-	// Write the filtered content back to the file
-	err = os.WriteFile(mdOutputFile, []byte(result.String()), 0644)
-	if err != nil {
-		return fmt.Errorf("error writing filtered markdown: %v", err)
-	}
+// deleteProcessedFiles removes every "*processed*" file left behind
+// under root, in pure Go so it behaves the same on every platform.
+func deleteProcessedFiles(root string) error {
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.Contains(filepath.Base(path), "processed") {
+			return os.Remove(path)
+		}
+		return nil
+	})
+}
+
+// varsFlag collects repeated --var key=value flags into a map.
+type varsFlag map[string]string
 
-	fmt.Printf("Successfully processed: %s\n", inputFile)
+func (v varsFlag) String() string {
+	return fmt.Sprintf("%v", map[string]string(v))
+}
+
+func (v varsFlag) Set(s string) error {
+	key, value, ok := strings.Cut(s, "=")
+	if !ok {
+		return fmt.Errorf("--var must be key=value, got %q", s)
+	}
+	v[key] = value
 	return nil
 }
 
 func main() {
-	if len(os.Args) != 5 {
-		fmt.Println("Usage: go run main.go <input_directory> <output_directory> <category> <tag>")
+	if len(os.Args) > 1 && os.Args[1] == "serve" {
+		if err := runServe(os.Args[2:]); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fs := flag.NewFlagSet("clean-html", flag.ExitOnError)
+	configPath := fs.String("config", "", "path to a cleanhtml.yaml rules config (default: built-in rules)")
+	jobs := fs.Int("jobs", runtime.NumCPU(), "number of files to process concurrently")
+	fmTemplateName := fs.String("frontmatter", frontmatter.Hugo, "built-in frontmatter template: hugo, jekyll, zola, or astro")
+	fmTemplatePath := fs.String("frontmatter-template", "", "path to a custom text/template frontmatter file (overrides --frontmatter)")
+	vars := varsFlag{}
+	fs.Var(vars, "var", "key=value custom frontmatter variable (repeatable)")
+	fs.Parse(os.Args[1:])
+
+	args := fs.Args()
+	if len(args) != 4 {
+		fmt.Println("Usage: clean-html [--config cleanhtml.yaml] [--jobs N] [--frontmatter hugo|jekyll|zola|astro] [--frontmatter-template file] [--var key=value]... <input_directory> <output_directory> <category> <tag>")
+		fmt.Println("       clean-html serve [--addr :8080] [--config cleanhtml.yaml] --input <directory>")
+		os.Exit(1)
+	}
+	if *jobs < 1 {
+		fmt.Println("Error: --jobs must be at least 1")
 		os.Exit(1)
 	}
 
-	inputDir := os.Args[1]
-	outputDir := os.Args[2]
-	category := os.Args[3]
-	tag := os.Args[4]
+	inputDir := args[0]
+	outputDir := args[1]
+	vars["category"] = args[2]
+	vars["tag"] = args[3]
+
+	logger := slog.New(slog.NewTextHandler(os.Stdout, nil))
+
+	cfg := rules.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := rules.Load(*configPath)
+		if err != nil {
+			fmt.Printf("Error loading rules config: %v\n", err)
+			os.Exit(1)
+		}
+		cfg = loaded
+	}
+	matcher := cfg.Matcher()
+
+	tmpl, err := frontmatter.Load(*fmTemplateName, *fmTemplatePath)
+	if err != nil {
+		fmt.Printf("Error loading frontmatter template: %v\n", err)
+		os.Exit(1)
+	}
 
 	// Check and create output directory
 	if err := checkAndCreateOutputDir(outputDir); err != nil {
@@ -277,30 +370,64 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Walk through directory
-	err := filepath.Walk(inputDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		// Process only .html files
-		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".html") {
-			if err := processHTMLFile(path, outputDir, category, tag); err != nil {
-				fmt.Printf("Error processing %s: %v\n", path, err)
+	// Walker feeds paths into a buffered channel; a pool of workers
+	// consumes them concurrently and reports outcomes to a collector.
+	paths := make(chan string, 64)
+	walkErrc := make(chan error, 1)
+	go walkFiles(inputDir, paths, walkErrc)
+
+	results := make(chan fileOutcome, 64)
+	var wg sync.WaitGroup
+	for i := 0; i < *jobs; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				if !strings.HasSuffix(strings.ToLower(path), ".html") {
+					results <- fileOutcome{Path: path, Status: "skipped"}
+					continue
+				}
+
+				start := time.Now()
+				stats, err := processHTMLFile(path, inputDir, outputDir, matcher, tmpl, vars)
+				outcome := fileOutcome{Path: path, Duration: time.Since(start), Stats: stats, Err: err, Status: "success"}
+				if err != nil {
+					outcome.Status = "error"
+				}
+				results <- outcome
 			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	var succeeded, skipped, failed int
+	for r := range results {
+		switch r.Status {
+		case "skipped":
+			skipped++
+			logger.Debug("skipped file", "path", r.Path)
+		case "error":
+			failed++
+			logger.Error("process file failed", "path", r.Path, "duration", r.Duration, "error", r.Err)
+		default:
+			succeeded++
+			logger.Info("processed file", "path", r.Path, "duration", r.Duration,
+				"input_bytes", r.Stats.InputBytes, "output_bytes", r.Stats.OutputBytes, "matches", r.Stats.Matches)
 		}
-		return nil
-	})
+	}
 
-	if err != nil {
+	if err := <-walkErrc; err != nil {
 		fmt.Printf("Error walking directory: %v\n", err)
 		os.Exit(1)
 	}
 
+	logger.Info("summary", "succeeded", succeeded, "skipped", skipped, "failed", failed)
+
 	// clean processed files
-	fmt.Println("Deleting processed files...")
-	cmd := exec.Command("find", inputDir, "-type", "f", "-name", "*processed*", "-delete")
-	if err := cmd.Run(); err != nil {
+	if err := deleteProcessedFiles(inputDir); err != nil {
 		fmt.Printf("error deleting processed files: %v\n", err)
 		os.Exit(2)
 	}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/truong11t2/clean-html/internal/frontmatter"
+	"github.com/truong11t2/clean-html/internal/rules"
+)
+
+func TestProcessHTMLFileMirrorsSubdirectories(t *testing.T) {
+	inputDir := t.TempDir()
+	outputDir := t.TempDir()
+
+	for _, dir := range []string{"section-a", "section-b"} {
+		if err := os.MkdirAll(filepath.Join(inputDir, dir), 0755); err != nil {
+			t.Fatalf("creating %s: %v", dir, err)
+		}
+	}
+	writeHTML := func(dir, heading string) string {
+		path := filepath.Join(inputDir, dir, "index.html")
+		body := "<html><body><h1>" + heading + "</h1><p>content for " + heading + "</p></body></html>"
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatalf("writing %s: %v", path, err)
+		}
+		return path
+	}
+	pathA := writeHTML("section-a", "Section A")
+	pathB := writeHTML("section-b", "Section B")
+
+	matcher := rules.DefaultConfig().Matcher()
+	tmpl, err := frontmatter.Load(frontmatter.Hugo, "")
+	if err != nil {
+		t.Fatalf("loading frontmatter template: %v", err)
+	}
+	vars := map[string]string{"category": "test", "tag": "test"}
+
+	if _, err := processHTMLFile(pathA, inputDir, outputDir, matcher, tmpl, vars); err != nil {
+		t.Fatalf("processing section-a: %v", err)
+	}
+	if _, err := processHTMLFile(pathB, inputDir, outputDir, matcher, tmpl, vars); err != nil {
+		t.Fatalf("processing section-b: %v", err)
+	}
+
+	mdA, err := os.ReadFile(filepath.Join(outputDir, "section-a", "index.md"))
+	if err != nil {
+		t.Fatalf("reading section-a output: %v", err)
+	}
+	mdB, err := os.ReadFile(filepath.Join(outputDir, "section-b", "index.md"))
+	if err != nil {
+		t.Fatalf("reading section-b output: %v", err)
+	}
+
+	if !strings.Contains(string(mdA), "Section A") {
+		t.Errorf("section-a output missing its own content: %q", mdA)
+	}
+	if !strings.Contains(string(mdB), "Section B") {
+		t.Errorf("section-b output missing its own content: %q", mdB)
+	}
+}
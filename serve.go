@@ -0,0 +1,188 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/truong11t2/clean-html/internal/mdconv"
+	"github.com/truong11t2/clean-html/internal/rules"
+	"golang.org/x/net/html"
+)
+
+// previewServer serves the raw, extracted, markdown, and diff views for
+// every HTML file under root. Nothing is cached: each request re-runs
+// extraction and conversion against the current rules, so editing the
+// rules config and refreshing shows the effect immediately.
+type previewServer struct {
+	root       string
+	configPath string
+	matcher    rules.Matcher
+}
+
+// runServe implements the "serve" subcommand.
+func runServe(args []string) error {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", ":8080", "address to listen on")
+	inputDir := fs.String("input", "", "directory of raw HTML files to preview")
+	configPath := fs.String("config", "", "path to a cleanhtml.yaml rules config (default: built-in rules)")
+	fs.Parse(args)
+
+	if *inputDir == "" {
+		return fmt.Errorf("--input is required")
+	}
+
+	cfg := rules.DefaultConfig()
+	if *configPath != "" {
+		loaded, err := rules.Load(*configPath)
+		if err != nil {
+			return err
+		}
+		cfg = loaded
+	}
+
+	srv := &previewServer{root: *inputDir, configPath: *configPath, matcher: cfg.Matcher()}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", srv.handleIndex)
+	mux.HandleFunc("/raw/", srv.handleRaw)
+	mux.HandleFunc("/extracted/", srv.handleExtracted)
+	mux.HandleFunc("/markdown/", srv.handleMarkdown)
+	mux.HandleFunc("/diff/", srv.handleDiff)
+
+	fmt.Printf("Serving %s on %s (raw/extracted/markdown/diff views, no caching)\n", *inputDir, *addr)
+	return http.ListenAndServe(*addr, mux)
+}
+
+// htmlFiles lists every .html file under root, relative to root.
+func (s *previewServer) htmlFiles() ([]string, error) {
+	var files []string
+	err := filepath.Walk(s.root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && strings.HasSuffix(strings.ToLower(path), ".html") {
+			rel, err := filepath.Rel(s.root, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	return files, err
+}
+
+// readRel reads the file named by the request path with prefix
+// stripped, resolved relative to root.
+func (s *previewServer) readRel(r *http.Request, prefix string) (rel string, content []byte, err error) {
+	rel = strings.TrimPrefix(r.URL.Path, prefix)
+	content, err = os.ReadFile(filepath.Join(s.root, rel))
+	return rel, content, err
+}
+
+// extractedHTML parses raw HTML and re-runs the extraction rules
+// against it, returning the same wrapped document processHTMLFile
+// would have produced.
+func (s *previewServer) extractedHTML(raw []byte) (string, error) {
+	doc, err := html.Parse(bytes.NewReader(raw))
+	if err != nil {
+		return "", err
+	}
+	matcher := s.matcher
+	if s.configPath != "" {
+		cfg, err := rules.Load(s.configPath)
+		if err != nil {
+			return "", err
+		}
+		matcher = cfg.Matcher()
+	}
+	return renderExtractedHTML(extractContent(doc, matcher)), nil
+}
+
+func (s *previewServer) handleIndex(w http.ResponseWriter, r *http.Request) {
+	files, err := s.htmlFiles()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, "<!DOCTYPE html><html><body><h1>clean-html preview: %s</h1><ul>\n", s.root)
+	for _, f := range files {
+		fmt.Fprintf(w, "<li>%s &mdash; <a href=\"/raw/%s\">raw</a> | <a href=\"/extracted/%s\">extracted</a> | <a href=\"/markdown/%s\">markdown</a> | <a href=\"/diff/%s\">diff</a></li>\n", f, f, f, f, f)
+	}
+	fmt.Fprintln(w, "</ul></body></html>")
+}
+
+func (s *previewServer) handleRaw(w http.ResponseWriter, r *http.Request) {
+	_, content, err := s.readRel(r, "/raw/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(content)
+}
+
+func (s *previewServer) handleExtracted(w http.ResponseWriter, r *http.Request) {
+	_, content, err := s.readRel(r, "/extracted/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	outputHTML, err := s.extractedHTML(content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write([]byte(outputHTML))
+}
+
+func (s *previewServer) handleMarkdown(w http.ResponseWriter, r *http.Request) {
+	_, content, err := s.readRel(r, "/markdown/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	outputHTML, err := s.extractedHTML(content)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	extractedDoc, err := html.Parse(strings.NewReader(outputHTML))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	mdText := mdconv.Convert(extractedDoc, mdconv.Options{RewriteLink: mdconv.StripIndexHTML})
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write([]byte(mdText))
+}
+
+func (s *previewServer) handleDiff(w http.ResponseWriter, r *http.Request) {
+	rel, _, err := s.readRel(r, "/diff/")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html><head><title>diff: %s</title><style>
+body{margin:0;font-family:sans-serif}
+.cols{display:flex}
+.col{width:50%%;box-sizing:border-box;padding:1em}
+.col h2{margin-top:0}
+iframe{width:100%%;height:calc(100vh - 3em);border:1px solid #ccc}
+</style></head>
+<body>
+<div class="cols">
+<div class="col"><h2>raw</h2><iframe src="/raw/%s"></iframe></div>
+<div class="col"><h2>extracted</h2><iframe src="/extracted/%s"></iframe></div>
+</div>
+</body></html>`, rel, rel, rel)
+}